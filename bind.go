@@ -0,0 +1,200 @@
+package httplib
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+)
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+type bindContextKey int
+
+const boundValueKey bindContextKey = iota
+
+// Bind is a middleware that decodes the request into a fresh value of
+// target's type and stashes it in the request context, based on the
+// request's Content-Type (or the query string, for GET/DELETE). Downstream
+// handlers retrieve it with Bound.
+func Bind(target interface{}) func(http.Handler) http.Handler {
+	t := reflect.TypeOf(target)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			v := reflect.New(t).Interface()
+			if err := BindRequest(r, v); err != nil {
+				writeErrResponse(w, r, err)
+				return
+			}
+			ctx := context.WithValue(r.Context(), boundValueKey, v)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Bound retrieves the value decoded by Bind from the request context, typed
+// as T -- always a pointer to the type passed to Bind, whether Bind got
+// MyParams{} or &MyParams{}, e.g. Bound[*MyParams](r) -- or the zero value
+// and false if Bind hasn't run or bound a different type.
+func Bound[T any](r *http.Request) (T, bool) {
+	v, ok := r.Context().Value(boundValueKey).(T)
+	return v, ok
+}
+
+// BindRequest decodes r into v based on r's Content-Type. GET and DELETE
+// requests are bound from the query string instead of the body. v must be
+// a pointer to a struct; fields are matched using "json", "xml", "form" or
+// "query" tags, falling back to the field name.
+func BindRequest(r *http.Request, v interface{}) error {
+	if r.Method == http.MethodGet || r.Method == http.MethodDelete {
+		return bindValues(r.URL.Query(), "query", v)
+	}
+
+	buf, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return &HandlerError{Status: http.StatusBadRequest, Message: "Failed to read body", Err: err, Public: true}
+	}
+	r.Body = nopCloser{bytes.NewReader(buf)}
+	if len(buf) == 0 {
+		return &HandlerError{Status: http.StatusBadRequest, Message: "Request body is required"}
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json":
+		if err := json.Unmarshal(buf, v); err != nil {
+			return &HandlerError{Status: http.StatusBadRequest, Message: "Failed to decode JSON body", Err: err, Public: true}
+		}
+	case "application/xml", "text/xml":
+		if err := xml.Unmarshal(buf, v); err != nil {
+			return &HandlerError{Status: http.StatusBadRequest, Message: "Failed to decode XML body", Err: err, Public: true}
+		}
+	case "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(buf))
+		if err != nil {
+			return &HandlerError{Status: http.StatusBadRequest, Message: "Failed to parse form body", Err: err, Public: true}
+		}
+		return bindValues(values, "form", v)
+	case "multipart/form-data":
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			return &HandlerError{Status: http.StatusBadRequest, Message: "Failed to parse multipart body", Err: err, Public: true}
+		}
+		return bindValues(url.Values(r.MultipartForm.Value), "form", v)
+	default:
+		return &HandlerError{Status: http.StatusBadRequest, Message: "Unsupported Content-Type: " + mediaType}
+	}
+	return nil
+}
+
+// bindValues populates the struct pointed to by v from values, matching
+// fields by the given tag name and recursing into nested structs.
+func bindValues(values url.Values, tag string, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return &HandlerError{Status: http.StatusInternalServerError, Message: "Bind target must be a pointer to struct"}
+	}
+	return bindStruct(values, tag, rv.Elem())
+}
+
+func bindStruct(values url.Values, tag string, rv reflect.Value) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		fv := rv.Field(i)
+		if !fv.CanSet() {
+			continue
+		}
+		if fv.Kind() == reflect.Struct && !implementsTextUnmarshaler(fv) {
+			if err := bindStruct(values, tag, fv); err != nil {
+				return err
+			}
+			continue
+		}
+		name := field.Tag.Get(tag)
+		if name == "" {
+			name = field.Name
+		}
+		if name == "-" {
+			continue
+		}
+		raw, ok := values[name]
+		if !ok || len(raw) == 0 {
+			continue
+		}
+		if err := setFieldValue(fv, raw); err != nil {
+			return &HandlerError{Status: http.StatusBadRequest, Message: "Invalid value for " + name, Err: err, Public: true}
+		}
+	}
+	return nil
+}
+
+func setFieldValue(fv reflect.Value, raw []string) error {
+	if fv.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(fv.Type(), len(raw), len(raw))
+		for i, s := range raw {
+			if err := setScalar(slice.Index(i), s); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+		return nil
+	}
+	return setScalar(fv, raw[0])
+}
+
+// implementsTextUnmarshaler reports whether fv's address implements
+// encoding.TextUnmarshaler, the way time.Time and similar struct-shaped
+// scalars typically do.
+func implementsTextUnmarshaler(fv reflect.Value) bool {
+	return fv.CanAddr() && fv.Addr().Type().Implements(textUnmarshalerType)
+}
+
+func setScalar(fv reflect.Value, s string) error {
+	if fv.CanAddr() {
+		if tu, ok := fv.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return tu.UnmarshalText([]byte(s))
+		}
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported field kind %s", fv.Kind())
+	}
+	return nil
+}
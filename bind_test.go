@@ -0,0 +1,158 @@
+package httplib
+
+import (
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBindRequestQueryTimeField(t *testing.T) {
+	type params struct {
+		CreatedAt time.Time `query:"created_at"`
+		Limit     int       `query:"limit"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/?created_at=2020-01-01T00:00:00Z&limit=10", nil)
+
+	var p params
+	if err := BindRequest(r, &p); err != nil {
+		t.Fatalf("BindRequest: %v", err)
+	}
+
+	want, err := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+	if err != nil {
+		t.Fatalf("time.Parse: %v", err)
+	}
+	if !p.CreatedAt.Equal(want) {
+		t.Errorf("CreatedAt = %v, want %v", p.CreatedAt, want)
+	}
+	if p.Limit != 10 {
+		t.Errorf("Limit = %d, want 10", p.Limit)
+	}
+}
+
+func TestBindRequestContentTypes(t *testing.T) {
+	type body struct {
+		Name string `json:"name" xml:"name" form:"name"`
+	}
+
+	cases := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{"json", "application/json", `{"name":"Bob"}`},
+		{"xml", "application/xml", `<body><name>Bob</name></body>`},
+		{"form", "application/x-www-form-urlencoded", url.Values{"name": {"Bob"}}.Encode()},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+			r.Header.Set("Content-Type", tc.contentType)
+
+			var b body
+			if err := BindRequest(r, &b); err != nil {
+				t.Fatalf("BindRequest: %v", err)
+			}
+			if b.Name != "Bob" {
+				t.Errorf("Name = %q, want %q", b.Name, "Bob")
+			}
+		})
+	}
+}
+
+func TestBindRequestMultipartForm(t *testing.T) {
+	type body struct {
+		Name string `form:"name"`
+	}
+
+	var buf strings.Builder
+	mw := multipart.NewWriter(&buf)
+	if err := mw.WriteField("name", "Bob"); err != nil {
+		t.Fatalf("WriteField: %v", err)
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(buf.String()))
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+
+	var b body
+	if err := BindRequest(r, &b); err != nil {
+		t.Fatalf("BindRequest: %v", err)
+	}
+	if b.Name != "Bob" {
+		t.Errorf("Name = %q, want %q", b.Name, "Bob")
+	}
+}
+
+func TestBindRequestUnsupportedContentType(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("whatever"))
+	r.Header.Set("Content-Type", "application/octet-stream")
+
+	var v struct{}
+	err := BindRequest(r, &v)
+	if err == nil {
+		t.Fatal("BindRequest: want error for unsupported content type")
+	}
+	herr, ok := err.(*HandlerError)
+	if !ok || herr.Status != http.StatusBadRequest {
+		t.Errorf("err = %v, want a 400 *HandlerError", err)
+	}
+}
+
+func TestBindRequestQueryNestedStructAndSlice(t *testing.T) {
+	type address struct {
+		City string `query:"city"`
+	}
+	type params struct {
+		Name    string `query:"name"`
+		Address address
+		Tags    []string `query:"tags"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/?name=Bob&city=NYC&tags=a&tags=b", nil)
+
+	var p params
+	if err := BindRequest(r, &p); err != nil {
+		t.Fatalf("BindRequest: %v", err)
+	}
+	if p.Name != "Bob" {
+		t.Errorf("Name = %q, want %q", p.Name, "Bob")
+	}
+	if p.Address.City != "NYC" {
+		t.Errorf("Address.City = %q, want %q", p.Address.City, "NYC")
+	}
+	if want := []string{"a", "b"}; len(p.Tags) != len(want) || p.Tags[0] != want[0] || p.Tags[1] != want[1] {
+		t.Errorf("Tags = %v, want %v", p.Tags, want)
+	}
+}
+
+func TestBoundTypedAccessor(t *testing.T) {
+	type params struct {
+		Limit int `query:"limit"`
+	}
+
+	handler := Bind(params{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, ok := Bound[*params](r)
+		if !ok {
+			t.Fatal("Bound[*params]: ok = false")
+		}
+		if p.Limit != 10 {
+			t.Errorf("Limit = %d, want 10", p.Limit)
+		}
+
+		if _, ok := Bound[*int](r); ok {
+			t.Error("Bound[*int]: ok = true, want false for a mismatched type")
+		}
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/?limit=10", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+}
@@ -0,0 +1,48 @@
+package httplib
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+type contextKey int
+
+const requestIDKey contextKey = iota
+
+// RequestID is a middleware that ensures every request carries an
+// X-Request-ID header, generating one if neither the context (already set
+// by an outer RequestID, e.g. the one LogRequest wraps every handler with)
+// nor the client supplied one, and stashes it in the request context so
+// downstream middleware and handlers (LogRequest, Handle) can read it back.
+// It is safe to nest: an inner RequestID reuses the id an outer one set.
+func RequestID(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := RequestIDFromContext(r.Context())
+		if id == "" {
+			id = r.Header.Get("X-Request-ID")
+		}
+		if id == "" {
+			id = newRequestID()
+		}
+		w.Header().Set("X-Request-ID", id)
+		ctx := context.WithValue(r.Context(), requestIDKey, id)
+		handler.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequestIDFromContext returns the request ID stashed by RequestID, or ""
+// if the middleware hasn't run for this request.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+func newRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b[:])
+}
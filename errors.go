@@ -0,0 +1,93 @@
+package httplib
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"runtime"
+)
+
+// Endpoint is an HTTP handler that reports failure by returning an error
+// instead of writing one to the ResponseWriter itself. Handle adapts it
+// into a standard http.HandlerFunc.
+type Endpoint func(http.ResponseWriter, *http.Request) error
+
+// HandlerError is an error that carries the HTTP status and machine-readable
+// code to report back to the client. Err, if set, is only echoed to the
+// client as the response's "details" when Public is true -- by default it's
+// treated as internal-only (e.g. a wrapped DB error), since Message is what
+// the client is meant to see. writeErrResponse does not log a
+// HandlerError's Err; only errors that aren't a *HandlerError are logged
+// there.
+type HandlerError struct {
+	Status  int
+	Message string
+	Code    string
+	Err     error
+	Public  bool
+}
+
+func (e *HandlerError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *HandlerError) Unwrap() error { return e.Err }
+
+// ErrResponse is the JSON body written for any endpoint or middleware error.
+type ErrResponse struct {
+	Status    int    `json:"status"`
+	Error     string `json:"error"`
+	Message   string `json:"message"`
+	Details   string `json:"details,omitempty"`
+	Code      string `json:"code,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Handle adapts an Endpoint into an http.HandlerFunc. When the endpoint
+// returns an error, Handle writes a structured ErrResponse JSON body instead
+// of letting the handler write its own ad-hoc error text.
+func Handle(ep Endpoint) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := ep(w, r); err != nil {
+			writeErrResponse(w, r, err)
+		}
+	}
+}
+
+// writeErrResponse marshals err as an ErrResponse and writes it to w.
+// Errors that aren't a *HandlerError are treated as unexpected: the error
+// and a stack trace are logged server-side through the request's logger,
+// and the client gets a generic 500 with no Details, the same way Recover
+// keeps panic tracebacks server-side only. Any other HandlerError's Err is
+// only echoed to the client when Public is set.
+func writeErrResponse(w http.ResponseWriter, r *http.Request, err error) {
+	var herr *HandlerError
+	if !errors.As(err, &herr) {
+		var buf [4096]byte
+		stack := buf[:runtime.Stack(buf[:], false)]
+		LoggerFromContext(r.Context()).WithFields(map[string]interface{}{
+			"traceback": string(stack),
+		}).Error(err.Error())
+		herr = &HandlerError{
+			Status:  http.StatusInternalServerError,
+			Message: "Internal server error",
+			Err:     err,
+		}
+	}
+	resp := ErrResponse{
+		Status:    herr.Status,
+		Error:     http.StatusText(herr.Status),
+		Message:   herr.Message,
+		Code:      herr.Code,
+		RequestID: RequestIDFromContext(r.Context()),
+	}
+	if herr.Err != nil && herr.Public {
+		resp.Details = herr.Err.Error()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(herr.Status)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,106 @@
+package httplib
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestWriteErrResponsePlainErrorNotPublic ensures an endpoint returning a
+// plain, unwrapped error (the common case, not a *HandlerError) never
+// echoes its message or stack trace to the client -- only Recover's
+// explicit, Public-less HandlerError{} pattern is the model here.
+func TestWriteErrResponsePlainErrorNotPublic(t *testing.T) {
+	handler := Handle(func(w http.ResponseWriter, r *http.Request) error {
+		return errors.New("db dial tcp 10.0.0.5:5432: connection refused")
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	handler(w, r)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+	var resp ErrResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if resp.Details != "" {
+		t.Errorf("Details = %q, want empty -- internal error leaked to client", resp.Details)
+	}
+	if resp.Message != "Internal server error" {
+		t.Errorf("Message = %q, want generic message", resp.Message)
+	}
+}
+
+// TestHandleSuccess ensures Handle leaves a successful Endpoint's own
+// response untouched -- writeErrResponse only runs when the endpoint
+// returns an error.
+func TestHandleSuccess(t *testing.T) {
+	handler := Handle(func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("ok"))
+		return nil
+	})
+
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	w := httptest.NewRecorder()
+	handler(w, r)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusCreated)
+	}
+	if w.Body.String() != "ok" {
+		t.Errorf("body = %q, want %q", w.Body.String(), "ok")
+	}
+}
+
+// TestWriteErrResponseIncludesRequestID ensures ErrResponse.RequestID is
+// populated from the context RequestID stashes, so clients can correlate an
+// error with server-side logs.
+func TestWriteErrResponseIncludesRequestID(t *testing.T) {
+	handler := RequestID(Handle(func(w http.ResponseWriter, r *http.Request) error {
+		return &HandlerError{Status: http.StatusBadRequest, Message: "bad input"}
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	headerID := w.Header().Get("X-Request-ID")
+	var resp ErrResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if resp.RequestID != headerID {
+		t.Errorf("RequestID = %q, want %q", resp.RequestID, headerID)
+	}
+}
+
+// TestWriteErrResponseHandlerErrorPublic ensures a *HandlerError explicitly
+// marked Public still has its Err echoed to the client.
+func TestWriteErrResponseHandlerErrorPublic(t *testing.T) {
+	handler := Handle(func(w http.ResponseWriter, r *http.Request) error {
+		return &HandlerError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid input",
+			Err:     errors.New("limit must be positive"),
+			Public:  true,
+		}
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	handler(w, r)
+
+	var resp ErrResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if resp.Details != "limit must be positive" {
+		t.Errorf("Details = %q, want %q", resp.Details, "limit must be positive")
+	}
+}
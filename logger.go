@@ -0,0 +1,40 @@
+package httplib
+
+import "context"
+
+// Logger is the logging interface httplib depends on. It's small enough
+// that most structured loggers satisfy it directly or through a thin
+// adapter -- see ZerologLogger, SlogLogger, and the logrusbridge
+// subpackage -- so callers aren't forced to import logrus just to use this
+// package.
+type Logger interface {
+	Info(args ...interface{})
+	Error(args ...interface{})
+	WithFields(fields map[string]interface{}) Logger
+}
+
+type loggerContextKey int
+
+const loggerKey loggerContextKey = iota
+
+// WithLogger returns a copy of ctx carrying logger, retrievable with
+// LoggerFromContext.
+func WithLogger(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, logger)
+}
+
+// LoggerFromContext returns the Logger stashed by LogRequest, or a no-op
+// Logger if none is set, so handlers can always log safely even outside a
+// LogRequest-wrapped request (e.g. in tests).
+func LoggerFromContext(ctx context.Context) Logger {
+	if logger, ok := ctx.Value(loggerKey).(Logger); ok {
+		return logger
+	}
+	return noopLogger{}
+}
+
+type noopLogger struct{}
+
+func (noopLogger) Info(args ...interface{})                   {}
+func (noopLogger) Error(args ...interface{})                  {}
+func (l noopLogger) WithFields(map[string]interface{}) Logger { return l }
@@ -0,0 +1,66 @@
+package httplib
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/rs/zerolog"
+)
+
+// ZerologLogger adapts a zerolog.Logger to Logger.
+type ZerologLogger struct {
+	logger zerolog.Logger
+	fields map[string]interface{}
+}
+
+// NewZerologLogger wraps l as a Logger.
+func NewZerologLogger(l zerolog.Logger) Logger {
+	return ZerologLogger{logger: l}
+}
+
+func (l ZerologLogger) Info(args ...interface{}) {
+	l.withFields(l.logger.Info()).Msg(fmt.Sprint(args...))
+}
+
+func (l ZerologLogger) Error(args ...interface{}) {
+	l.withFields(l.logger.Error()).Msg(fmt.Sprint(args...))
+}
+
+func (l ZerologLogger) withFields(e *zerolog.Event) *zerolog.Event {
+	for k, v := range l.fields {
+		e = e.Interface(k, v)
+	}
+	return e
+}
+
+func (l ZerologLogger) WithFields(fields map[string]interface{}) Logger {
+	merged := make(map[string]interface{}, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return ZerologLogger{logger: l.logger, fields: merged}
+}
+
+// SlogLogger adapts a *slog.Logger (Go 1.21+) to Logger.
+type SlogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger wraps l as a Logger.
+func NewSlogLogger(l *slog.Logger) Logger {
+	return SlogLogger{logger: l}
+}
+
+func (l SlogLogger) Info(args ...interface{})  { l.logger.Info(fmt.Sprint(args...)) }
+func (l SlogLogger) Error(args ...interface{}) { l.logger.Error(fmt.Sprint(args...)) }
+
+func (l SlogLogger) WithFields(fields map[string]interface{}) Logger {
+	attrs := make([]any, 0, len(fields)*2)
+	for k, v := range fields {
+		attrs = append(attrs, k, v)
+	}
+	return SlogLogger{logger: l.logger.With(attrs...)}
+}
@@ -0,0 +1,68 @@
+package httplib
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestZerologLoggerWritesLevelMessageAndFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewZerologLogger(zerolog.New(&buf))
+
+	logger.WithFields(map[string]interface{}{"status": 200}).Info("request done")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if entry["level"] != "info" {
+		t.Errorf("level = %v, want %q", entry["level"], "info")
+	}
+	if entry["message"] != "request done" {
+		t.Errorf("message = %v, want %q", entry["message"], "request done")
+	}
+	if entry["status"] != float64(200) {
+		t.Errorf("status = %v, want 200", entry["status"])
+	}
+}
+
+func TestZerologLoggerWithFieldsMerges(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewZerologLogger(zerolog.New(&buf)).WithFields(map[string]interface{}{"a": 1})
+	logger = logger.WithFields(map[string]interface{}{"b": 2})
+	logger.Error("failed")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if entry["a"] != float64(1) || entry["b"] != float64(2) {
+		t.Errorf("entry = %v, want both a and b present", entry)
+	}
+}
+
+func TestSlogLoggerWritesLevelMessageAndFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	logger.WithFields(map[string]interface{}{"status": 200}).Info("request done")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if entry["level"] != "INFO" {
+		t.Errorf("level = %v, want %q", entry["level"], "INFO")
+	}
+	if !strings.Contains(entry["msg"].(string), "request done") {
+		t.Errorf("msg = %v, want to contain %q", entry["msg"], "request done")
+	}
+	if entry["status"] != float64(200) {
+		t.Errorf("status = %v, want 200", entry["status"])
+	}
+}
@@ -0,0 +1,44 @@
+// Package logrusbridge adapts *logrus.Logger to httplib.Logger, and provides
+// the RunHTTP back-compat shim. It is split out of httplib itself so that
+// callers using the zerolog or slog adapters aren't forced to pull in
+// github.com/Sirupsen/logrus.
+package logrusbridge
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/wrapp/httplib"
+)
+
+// Logger adapts a *logrus.Logger to httplib.Logger, preserving the field and
+// level semantics callers of the old package-level logger relied on.
+type Logger struct {
+	entry *logrus.Entry
+}
+
+// NewLogger wraps l as an httplib.Logger.
+func NewLogger(l *logrus.Logger) httplib.Logger {
+	return Logger{entry: logrus.NewEntry(l)}
+}
+
+func (l Logger) Info(args ...interface{})  { l.entry.Info(args...) }
+func (l Logger) Error(args ...interface{}) { l.entry.Error(args...) }
+
+func (l Logger) WithFields(fields map[string]interface{}) httplib.Logger {
+	return Logger{entry: l.entry.WithFields(logrus.Fields(fields))}
+}
+
+// RunHTTP starts a webserver with Wrapp logging and panic recovery.
+// The port number is fetched from the environment variable SERVICE_PORT.
+//
+// It is a thin wrapper around httplib.NewServer and Server.Run kept for
+// backward compatibility; new callers should build an httplib.Server
+// directly with a Logger of their choice to get graceful shutdown, TLS, and
+// the other options.
+func RunHTTP(serviceName string, mylog *logrus.Logger, h http.Handler) {
+	if err := httplib.NewServer(serviceName, NewLogger(mylog), h).Run(context.Background()); err != nil {
+		mylog.Fatal(err)
+	}
+}
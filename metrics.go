@@ -0,0 +1,61 @@
+package httplib
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics holds the Prometheus collectors LogRequest records request count,
+// latency, and response size to, labeled by method and status class (e.g.
+// "2xx", "5xx"). Build one with NewMetrics and pass it to LogRequest via
+// WithMetrics.
+type Metrics struct {
+	registry *prometheus.Registry
+	requests *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	size     *prometheus.HistogramVec
+}
+
+// NewMetrics registers a fresh set of collectors on registry.
+func NewMetrics(registry *prometheus.Registry) *Metrics {
+	m := &Metrics{
+		registry: registry,
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Total HTTP requests, by method and status class.",
+		}, []string{"method", "status_class"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency in seconds, by method and status class.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "status_class"}),
+		size: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_response_size_bytes",
+			Help:    "HTTP response size in bytes, by method and status class.",
+			Buckets: prometheus.ExponentialBuckets(100, 10, 6),
+		}, []string{"method", "status_class"}),
+	}
+	registry.MustRegister(m.requests, m.latency, m.size)
+	return m
+}
+
+// Handler serves the registered metrics in the Prometheus exposition format.
+// Mount it wherever your service exposes /metrics.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+func (m *Metrics) observe(method string, status int, took time.Duration, size int) {
+	class := statusClass(status)
+	m.requests.WithLabelValues(method, class).Inc()
+	m.latency.WithLabelValues(method, class).Observe(took.Seconds())
+	m.size.WithLabelValues(method, class).Observe(float64(size))
+}
+
+func statusClass(status int) string {
+	return strconv.Itoa(status/100) + "xx"
+}
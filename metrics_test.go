@@ -0,0 +1,54 @@
+package httplib
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestStatusClass(t *testing.T) {
+	cases := map[int]string{200: "2xx", 301: "3xx", 404: "4xx", 500: "5xx"}
+	for status, want := range cases {
+		if got := statusClass(status); got != want {
+			t.Errorf("statusClass(%d) = %q, want %q", status, got, want)
+		}
+	}
+}
+
+func TestMetricsObserveExposesCounters(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m := NewMetrics(registry)
+
+	m.observe(http.MethodGet, http.StatusOK, 10*time.Millisecond, 42)
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := w.Body.String()
+	if !strings.Contains(body, `http_requests_total{method="GET",status_class="2xx"} 1`) {
+		t.Errorf("metrics output missing expected counter, got:\n%s", body)
+	}
+}
+
+func TestLogRequestRecordsMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m := NewMetrics(registry)
+
+	handler := LogRequest(&fakeLogger{}, WithMetrics(m))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	mw := httptest.NewRecorder()
+	m.Handler().ServeHTTP(mw, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.Contains(mw.Body.String(), `http_requests_total{method="GET",status_class="4xx"} 1`) {
+		t.Errorf("metrics output missing expected counter, got:\n%s", mw.Body.String())
+	}
+}
@@ -0,0 +1,178 @@
+package httplib
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+)
+
+// SchemaRoute describes which request a set of schemas applies to, and
+// which parts of the request they validate. Method and ContentType match
+// exactly when set; a zero value matches anything. Pattern, if set, is
+// matched against r.URL.Path, and its named capture groups become the path
+// variables validated against Vars.
+type SchemaRoute struct {
+	Method      string
+	ContentType string
+	Pattern     *regexp.Regexp
+	Body        *SchemaValidator
+	Query       *SchemaValidator
+	Vars        *SchemaValidator
+}
+
+// SchemaSet dispatches request validation to different schemas per route,
+// so a single middleware can validate every endpoint's payloads from e.g.
+// one embedded schema bundle.
+type SchemaSet struct {
+	routes []SchemaRoute
+}
+
+// NewSchemaSet builds a SchemaSet that tries each route in order, using the
+// first one that matches the request.
+func NewSchemaSet(routes ...SchemaRoute) *SchemaSet {
+	return &SchemaSet{routes: routes}
+}
+
+// Middleware returns an http middleware that validates the request against
+// the first matching route's schemas, in the order Vars, Query, Body.
+func (s *SchemaSet) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route, vars := s.match(r)
+			if route == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if route.Vars != nil {
+				if !s.validateValues(w, r, route.Vars, varsToValues(vars)) {
+					return
+				}
+			}
+			if route.Query != nil {
+				if !s.validateValues(w, r, route.Query, r.URL.Query()) {
+					return
+				}
+			}
+			if route.Body != nil {
+				buf, err := ioutil.ReadAll(r.Body)
+				if err != nil {
+					writeErrResponse(w, r, &HandlerError{Status: http.StatusBadRequest, Message: "Failed to read body", Err: err, Public: true})
+					return
+				}
+				r.Body = nopCloser{bytes.NewReader(buf)}
+				result, err := route.Body.Validate(buf)
+				if err != nil {
+					writeErrResponse(w, r, &HandlerError{Status: http.StatusBadRequest, Message: "Failed to validate", Err: err, Public: true})
+					return
+				}
+				if !result.Valid() {
+					writeValidationResult(w, result)
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (s *SchemaSet) validateValues(w http.ResponseWriter, r *http.Request, sv *SchemaValidator, values url.Values) bool {
+	buf, err := valuesToJSON(values)
+	if err != nil {
+		writeErrResponse(w, r, &HandlerError{Status: http.StatusInternalServerError, Message: "Failed to encode values for validation", Err: err})
+		return false
+	}
+	result, err := sv.Validate(buf)
+	if err != nil {
+		writeErrResponse(w, r, &HandlerError{Status: http.StatusBadRequest, Message: "Failed to validate", Err: err, Public: true})
+		return false
+	}
+	if !result.Valid() {
+		writeValidationResult(w, result)
+		return false
+	}
+	return true
+}
+
+// match returns the first route matching r, along with any named path
+// variables its Pattern captured.
+func (s *SchemaSet) match(r *http.Request) (*SchemaRoute, map[string]string) {
+	for i := range s.routes {
+		route := &s.routes[i]
+		if route.Method != "" && route.Method != r.Method {
+			continue
+		}
+		if route.ContentType != "" {
+			mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if mediaType != route.ContentType {
+				continue
+			}
+		}
+		if route.Pattern == nil {
+			return route, nil
+		}
+		m := route.Pattern.FindStringSubmatch(r.URL.Path)
+		if m == nil {
+			continue
+		}
+		vars := make(map[string]string)
+		for i, name := range route.Pattern.SubexpNames() {
+			if i == 0 || name == "" {
+				continue
+			}
+			vars[name] = m[i]
+		}
+		return route, vars
+	}
+	return nil, nil
+}
+
+func varsToValues(vars map[string]string) url.Values {
+	values := make(url.Values, len(vars))
+	for k, v := range vars {
+		values.Set(k, v)
+	}
+	return values
+}
+
+// valuesToJSON turns a url.Values into a JSON object, collapsing
+// single-value keys to scalars so simple schemas don't need to account for
+// the array-of-one case, and coercing each string value to a number or
+// boolean when it looks like one, so a schema declaring e.g.
+// {"type":"integer"} validates a query param the way it was meant to: as
+// the value it represents, not as the string url.Values always stores it as.
+func valuesToJSON(values url.Values) ([]byte, error) {
+	m := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		coerced := make([]interface{}, len(v))
+		for i, s := range v {
+			coerced[i] = coerceValue(s)
+		}
+		if len(coerced) == 1 {
+			m[k] = coerced[0]
+		} else {
+			m[k] = coerced
+		}
+	}
+	return json.Marshal(m)
+}
+
+// coerceValue converts a raw query/path string to the JSON type it looks
+// like it represents -- an integer, a float, or a boolean -- falling back
+// to the string itself otherwise.
+func coerceValue(s string) interface{} {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	if b, err := strconv.ParseBool(s); err == nil {
+		return b
+	}
+	return s
+}
@@ -0,0 +1,159 @@
+package httplib
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestSchemaSetQueryIntegerCoercion(t *testing.T) {
+	querySchema, err := NewSchemaValidatorFromMap(map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"limit": map[string]interface{}{"type": "integer"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewSchemaValidatorFromMap: %v", err)
+	}
+
+	set := NewSchemaSet(SchemaRoute{Method: http.MethodGet, Query: querySchema})
+
+	called := false
+	handler := set.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/?limit=10", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	if !called {
+		t.Fatalf("handler not called, response: %s", w.Body.String())
+	}
+	if w.Code != 0 && w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+}
+
+func TestSchemaSetMatchByMethodAndContentType(t *testing.T) {
+	postSchema, err := NewSchemaValidatorFromMap(map[string]interface{}{
+		"type":     "object",
+		"required": []interface{}{"name"},
+	})
+	if err != nil {
+		t.Fatalf("NewSchemaValidatorFromMap: %v", err)
+	}
+
+	set := NewSchemaSet(
+		SchemaRoute{Method: http.MethodPost, ContentType: "application/json", Body: postSchema},
+	)
+
+	handler := set.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	t.Run("matching route rejects invalid body", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("non-matching method bypasses validation", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != http.StatusOK {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+		}
+	})
+
+	t.Run("non-matching content-type bypasses validation", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+		r.Header.Set("Content-Type", "text/plain")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != http.StatusOK {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+		}
+	})
+}
+
+func TestSchemaSetMatchPatternVars(t *testing.T) {
+	varsSchema, err := NewSchemaValidatorFromMap(map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"id": map[string]interface{}{"type": "integer"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewSchemaValidatorFromMap: %v", err)
+	}
+
+	set := NewSchemaSet(
+		SchemaRoute{Pattern: regexp.MustCompile(`^/items/(?P<id>[^/]+)$`), Vars: varsSchema},
+	)
+
+	handler := set.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	t.Run("valid path var", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/items/42", nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != http.StatusOK {
+			t.Errorf("status = %d, body = %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("invalid path var", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/items/not-a-number", nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("non-matching path bypasses validation", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/other", nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != http.StatusOK {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+		}
+	})
+}
+
+func TestSchemaSetFirstMatchWins(t *testing.T) {
+	alwaysInvalid, err := NewSchemaValidatorFromMap(map[string]interface{}{
+		"type": "object", "required": []interface{}{"never-present"},
+	})
+	if err != nil {
+		t.Fatalf("NewSchemaValidatorFromMap: %v", err)
+	}
+
+	set := NewSchemaSet(
+		SchemaRoute{Method: http.MethodGet},
+		SchemaRoute{Method: http.MethodGet, Query: alwaysInvalid},
+	)
+
+	handler := set.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d -- the first matching route (no schemas) should have been used", w.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,179 @@
+package httplib
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/wrapp/env"
+)
+
+// Server is an HTTP server configured via functional options, wrapping a
+// *http.Server with Wrapp logging, panic recovery, and graceful shutdown.
+// Build one with NewServer and start it with Run.
+type Server struct {
+	name       string
+	log        Logger
+	handler    http.Handler
+	middleware []func(http.Handler) http.Handler
+	logOpts    []LogOption
+
+	port            string
+	certFile        string
+	keyFile         string
+	readTimeout     time.Duration
+	writeTimeout    time.Duration
+	idleTimeout     time.Duration
+	shutdownTimeout time.Duration
+	healthz         bool
+	readyz          bool
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithPort overrides the port the server listens on (default: the
+// SERVICE_PORT environment variable, or "8080").
+func WithPort(port string) Option {
+	return func(s *Server) { s.port = port }
+}
+
+// WithTLS makes the server listen with TLS using the given certificate and
+// key files.
+func WithTLS(certFile, keyFile string) Option {
+	return func(s *Server) {
+		s.certFile = certFile
+		s.keyFile = keyFile
+	}
+}
+
+// WithReadTimeout sets http.Server.ReadTimeout.
+func WithReadTimeout(d time.Duration) Option {
+	return func(s *Server) { s.readTimeout = d }
+}
+
+// WithWriteTimeout sets http.Server.WriteTimeout.
+func WithWriteTimeout(d time.Duration) Option {
+	return func(s *Server) { s.writeTimeout = d }
+}
+
+// WithIdleTimeout sets http.Server.IdleTimeout.
+func WithIdleTimeout(d time.Duration) Option {
+	return func(s *Server) { s.idleTimeout = d }
+}
+
+// WithShutdownTimeout bounds how long Run waits for in-flight requests to
+// drain after a shutdown signal before giving up (default: 15s).
+func WithShutdownTimeout(d time.Duration) Option {
+	return func(s *Server) { s.shutdownTimeout = d }
+}
+
+// WithMiddleware adds middleware between Recover/LogRequest and the handler,
+// applied in the order given.
+func WithMiddleware(mw ...func(http.Handler) http.Handler) Option {
+	return func(s *Server) { s.middleware = append(s.middleware, mw...) }
+}
+
+// WithHealthz serves an always-200 GET /healthz, bypassing the handler and
+// its middleware.
+func WithHealthz() Option {
+	return func(s *Server) { s.healthz = true }
+}
+
+// WithReadyz serves an always-200 GET /readyz, bypassing the handler and
+// its middleware.
+func WithReadyz() Option {
+	return func(s *Server) { s.readyz = true }
+}
+
+// WithLogOptions passes options through to the LogRequest middleware, e.g.
+// WithMaxLoggedBodyBytes or WithMetrics.
+func WithLogOptions(opts ...LogOption) Option {
+	return func(s *Server) { s.logOpts = append(s.logOpts, opts...) }
+}
+
+// NewServer builds a Server for serviceName, serving h behind LogRequest and
+// Recover plus any middleware supplied via WithMiddleware. logger is what
+// Recover and LogRequest log through; use NewZerologLogger, NewSlogLogger,
+// or logrusbridge.NewLogger to plug in an existing logger.
+func NewServer(serviceName string, logger Logger, h http.Handler, opts ...Option) *Server {
+	s := &Server{
+		name:            serviceName,
+		log:             logger,
+		handler:         h,
+		port:            env.Default("SERVICE_PORT", "8080"),
+		shutdownTimeout: 15 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *Server) buildHandler() http.Handler {
+	h := s.handler
+	for i := len(s.middleware) - 1; i >= 0; i-- {
+		h = s.middleware[i](h)
+	}
+	if s.healthz || s.readyz {
+		mux := http.NewServeMux()
+		if s.healthz {
+			mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+		}
+		if s.readyz {
+			mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+		}
+		mux.Handle("/", h)
+		h = mux
+	}
+	return LogRequest(s.log, s.logOpts...)(Recover(s.log)(h))
+}
+
+// Run starts the server and blocks until ctx is cancelled or the process
+// receives SIGINT/SIGTERM, then stops accepting new connections and drains
+// in-flight requests for up to the shutdown timeout before returning.
+func (s *Server) Run(ctx context.Context) error {
+	srv := &http.Server{
+		Addr:         ":" + s.port,
+		Handler:      s.buildHandler(),
+		ReadTimeout:  s.readTimeout,
+		WriteTimeout: s.writeTimeout,
+		IdleTimeout:  s.idleTimeout,
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		s.log.Info(fmt.Sprintf("Starting %s on port %s", s.name, s.port))
+		var err error
+		if s.certFile != "" || s.keyFile != "" {
+			err = srv.ListenAndServeTLS(s.certFile, s.keyFile)
+		} else {
+			err = srv.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
+			errCh <- err
+			return
+		}
+		errCh <- nil
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	s.log.Info(fmt.Sprintf("Shutting down %s", s.name))
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	return <-errCh
+}
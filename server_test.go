@@ -0,0 +1,90 @@
+package httplib
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestServerBuildHandlerMiddlewareOrder(t *testing.T) {
+	var order []string
+	mw := func(name string) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	s := NewServer("test", &fakeLogger{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		order = append(order, "handler")
+	}), WithMiddleware(mw("first"), mw("second")))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	s.buildHandler().ServeHTTP(w, r)
+
+	want := []string{"first", "second", "handler"}
+	if len(order) != len(want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+	for i, name := range want {
+		if order[i] != name {
+			t.Errorf("order[%d] = %q, want %q", i, order[i], name)
+		}
+	}
+}
+
+func TestServerBuildHandlerHealthzReadyzBypassMiddleware(t *testing.T) {
+	s := NewServer("test", &fakeLogger{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), WithHealthz(), WithReadyz(), WithMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+	}))
+	handler := s.buildHandler()
+
+	for _, path := range []string{"/healthz", "/readyz"} {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: status = %d, want %d", path, w.Code, http.StatusOK)
+		}
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("/: status = %d, want %d (middleware should still run for non-healthz/readyz paths)", w.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestServerRunGracefulShutdown(t *testing.T) {
+	s := NewServer("test", &fakeLogger{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), WithPort("0"), WithShutdownTimeout(time.Second))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+
+	// Run has no readiness signal to wait on; give the listener goroutine a
+	// moment to start before triggering shutdown.
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Run: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run did not return after context cancellation")
+	}
+}
@@ -4,24 +4,37 @@ import (
 	"bytes"
 	"encoding/json"
 	"io"
+	"io/fs"
 	"io/ioutil"
 	"net/http"
 
 	"github.com/xeipuuv/gojsonschema"
 )
 
+// ValidationError mirrors a single gojsonschema.ResultError, carrying enough
+// detail for a client to pinpoint which field failed and why.
+type ValidationError struct {
+	Context     string      `json:"context"`
+	Description string      `json:"description"`
+	Value       interface{} `json:"value"`
+}
+
 type ValidationResult struct {
 	gojsonschema.Result
 }
 
 func (vr ValidationResult) MarshalJSON() ([]byte, error) {
-	var errors []string
+	errs := make([]ValidationError, 0, len(vr.Errors()))
 	for _, e := range vr.Errors() {
-		errors = append(errors, e.Description())
+		errs = append(errs, ValidationError{
+			Context:     e.Context().String(),
+			Description: e.Description(),
+			Value:       e.Value(),
+		})
 	}
 	return json.Marshal(map[string]interface{}{
 		"valid":  vr.Valid(),
-		"errors": errors,
+		"errors": errs,
 	})
 }
 
@@ -31,33 +44,109 @@ type nopCloser struct {
 
 func (nopCloser) Close() error { return nil }
 
-// ValidateJSONSchema returns a http middleware that validates the supplied
-// JSON schema. Will panic if the schema file can't be found and/or is invalid
-func ValidateJSONSchema(path string) func(http.Handler) http.Handler {
-	schemaLoader := gojsonschema.NewReferenceLoader("file://" + path)
-	schema, err := gojsonschema.NewSchema(schemaLoader)
+// SchemaValidator validates JSON documents against a compiled schema. Build
+// one with NewSchemaValidator or one of the NewSchemaValidatorFrom* helpers,
+// which cover the ways a schema can be supplied: a file path, a raw string,
+// an embedded fs.FS, an HTTP(S) URL, or an inline map.
+type SchemaValidator struct {
+	schema *gojsonschema.Schema
+}
+
+// NewSchemaValidator compiles a SchemaValidator from any gojsonschema.JSONLoader.
+func NewSchemaValidator(loader gojsonschema.JSONLoader) (*SchemaValidator, error) {
+	schema, err := gojsonschema.NewSchema(loader)
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+	return &SchemaValidator{schema: schema}, nil
+}
+
+// NewSchemaValidatorFromFile compiles a schema from a JSON schema file on disk.
+func NewSchemaValidatorFromFile(path string) (*SchemaValidator, error) {
+	return NewSchemaValidator(gojsonschema.NewReferenceLoader("file://" + path))
+}
+
+// NewSchemaValidatorFromString compiles a schema from a raw JSON schema string.
+func NewSchemaValidatorFromString(schema string) (*SchemaValidator, error) {
+	return NewSchemaValidator(gojsonschema.NewStringLoader(schema))
+}
+
+// NewSchemaValidatorFromFS compiles a schema read from an embedded fs.FS,
+// e.g. one produced by a Go 1.16+ //go:embed directive.
+func NewSchemaValidatorFromFS(fsys fs.FS, name string) (*SchemaValidator, error) {
+	buf, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
 	}
+	return NewSchemaValidator(gojsonschema.NewBytesLoader(buf))
+}
+
+// NewSchemaValidatorFromURL compiles a schema fetched from an HTTP(S) URL.
+func NewSchemaValidatorFromURL(url string) (*SchemaValidator, error) {
+	return NewSchemaValidator(gojsonschema.NewReferenceLoader(url))
+}
+
+// NewSchemaValidatorFromMap compiles a schema from an inline Go value,
+// typically a map[string]interface{} literal.
+func NewSchemaValidatorFromMap(schema map[string]interface{}) (*SchemaValidator, error) {
+	return NewSchemaValidator(gojsonschema.NewGoLoader(schema))
+}
+
+// Validate validates a JSON document against the schema.
+func (sv *SchemaValidator) Validate(buf []byte) (*ValidationResult, error) {
+	result, err := sv.schema.Validate(gojsonschema.NewBytesLoader(buf))
+	if err != nil {
+		return nil, err
+	}
+	return &ValidationResult{*result}, nil
+}
+
+// Middleware returns an http middleware that validates the request body
+// against the schema, restoring r.Body afterwards so it can be chained in
+// front of Bind or a handler that reads the body itself.
+func (sv *SchemaValidator) Middleware() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			buf, err := ioutil.ReadAll(r.Body)
 			if err != nil {
-				http.Error(w, "Failed to read body: "+err.Error(), http.StatusBadRequest)
+				writeErrResponse(w, r, &HandlerError{Status: http.StatusBadRequest, Message: "Failed to read body", Err: err, Public: true})
 				return
 			}
-			validationResult, err := schema.Validate(gojsonschema.NewStringLoader(string(buf)))
+			r.Body = nopCloser{bytes.NewReader(buf)}
+			result, err := sv.Validate(buf)
 			if err != nil {
-				http.Error(w, "Failed to validate: "+err.Error(), http.StatusBadRequest)
+				writeErrResponse(w, r, &HandlerError{Status: http.StatusBadRequest, Message: "Failed to validate", Err: err, Public: true})
 				return
 			}
-			if !validationResult.Valid() {
-				validationResultJSON, _ := json.Marshal(ValidationResult{*validationResult})
-				http.Error(w, string(validationResultJSON), http.StatusBadRequest)
+			if !result.Valid() {
+				writeValidationResult(w, result)
 				return
 			}
-			r.Body = nopCloser{bytes.NewReader(buf)}
 			next.ServeHTTP(w, r)
 		})
 	}
 }
+
+func writeValidationResult(w http.ResponseWriter, result *ValidationResult) {
+	body, _ := json.Marshal(result)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	w.Write(body)
+}
+
+// ValidateJSONSchema returns a http middleware that validates the request
+// body against the JSON schema at path. Will panic if the schema file can't
+// be found and/or is invalid. It restores r.Body after reading it, so it
+// can be chained in front of Bind to validate the raw payload before
+// binding consumes it.
+//
+// For schemas loaded from somewhere other than a file, or for validating
+// more than just the body, build a SchemaValidator (or a SchemaSet)
+// directly instead.
+func ValidateJSONSchema(path string) func(http.Handler) http.Handler {
+	sv, err := NewSchemaValidatorFromFile(path)
+	if err != nil {
+		panic(err)
+	}
+	return sv.Middleware()
+}
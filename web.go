@@ -1,24 +1,27 @@
 package httplib
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"reflect"
 	"runtime"
 	"time"
-
-	"github.com/Sirupsen/logrus"
-	"github.com/wrapp/env"
 )
 
-var log *logrus.Logger
+const defaultMaxLoggedBodyBytes = 4 * 1024
 
 type loggedResponse struct {
-	w       http.ResponseWriter
-	started time.Time
-	status  int
-	size    int
-	body    string
+	w            http.ResponseWriter
+	started      time.Time
+	status       int
+	size         int
+	maxBodyBytes int
+	body         bytes.Buffer // only the first maxBodyBytes bytes of the response are kept, for logging
 }
 
 func (l *loggedResponse) Flush() {
@@ -34,7 +37,17 @@ func (l *loggedResponse) Write(b []byte) (int, error) {
 		// The status will be StatusOK if WriteHeader has not been called yet
 		l.status = http.StatusOK
 	}
-	l.body += string(b)
+	// Responses below 400 skip the buffer -- LogRequest only reads lw.body
+	// for a status >= 400, so keeping it would just be a wasted copy.
+	if l.status >= 400 {
+		if remaining := l.maxBodyBytes - l.body.Len(); remaining > 0 {
+			if len(b) > remaining {
+				l.body.Write(b[:remaining])
+			} else {
+				l.body.Write(b)
+			}
+		}
+	}
 	size, err := l.w.Write(b)
 	l.size += size
 	return size, err
@@ -45,72 +58,150 @@ func (l *loggedResponse) WriteHeader(status int) {
 	l.status = status
 }
 
-// Recover is a middleware that recovers a handler from an error and logs the traceback
-func Recover(handler http.Handler) http.Handler {
-	return http.HandlerFunc(
-		func(w http.ResponseWriter, r *http.Request) {
-			defer func() {
-				if rec := recover(); rec != nil {
-					var msg = "Unhandled panic: "
-					var buf [4096]byte
-					runtime.Stack(buf[:], true)
-					stack := buf[:runtime.Stack(buf[:], false)]
-					switch v := rec.(type) {
-					case string:
-						msg += v
-					default:
-						msg += reflect.TypeOf(v).String()
+// Hijack proxies http.Hijacker, so LogRequest doesn't break handlers that
+// take over the connection (e.g. websocket upgrades).
+func (l *loggedResponse) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := l.w.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("httplib: underlying ResponseWriter does not support Hijack")
+	}
+	return hj.Hijack()
+}
+
+// Push proxies http.Pusher, so LogRequest doesn't break HTTP/2 server push.
+func (l *loggedResponse) Push(target string, opts *http.PushOptions) error {
+	p, ok := l.w.(http.Pusher)
+	if !ok {
+		return http.ErrNotSupported
+	}
+	return p.Push(target, opts)
+}
+
+// CloseNotify proxies the deprecated http.CloseNotifier, for handlers that
+// still rely on it to detect a client disconnect.
+func (l *loggedResponse) CloseNotify() <-chan bool {
+	cn, ok := l.w.(http.CloseNotifier)
+	if !ok {
+		return make(chan bool)
+	}
+	return cn.CloseNotify()
+}
+
+// Recover returns a middleware that recovers a handler from a panic, logs
+// the traceback through logger, and writes the same ErrResponse JSON shape
+// Handle uses for endpoint errors.
+func Recover(logger Logger) func(http.Handler) http.Handler {
+	return func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				defer func() {
+					if rec := recover(); rec != nil {
+						var msg = "Unhandled panic: "
+						var buf [4096]byte
+						runtime.Stack(buf[:], true)
+						stack := buf[:runtime.Stack(buf[:], false)]
+						switch v := rec.(type) {
+						case string:
+							msg += v
+						default:
+							msg += reflect.TypeOf(v).String()
+						}
+						logger.WithFields(map[string]interface{}{
+							"traceback": string(stack),
+						}).Error(msg)
+						writeErrResponse(w, r, &HandlerError{
+							Status:  http.StatusInternalServerError,
+							Message: msg,
+							Err:     errors.New(string(stack)),
+						})
 					}
-					log.WithFields(logrus.Fields{
-						"traceback": string(stack),
-					}).Error(msg)
-					http.Error(w, fmt.Sprintf("%s \n%s", msg, stack), http.StatusInternalServerError)
-				}
-			}()
-			handler.ServeHTTP(w, r)
-		})
+				}()
+				handler.ServeHTTP(w, r)
+			})
+	}
+}
+
+type logConfig struct {
+	maxBodyBytes int
+	metrics      *Metrics
 }
 
-// LogRequest is a middleware that logs a request
+// LogOption configures LogRequest.
+type LogOption func(*logConfig)
+
+// WithMaxLoggedBodyBytes caps how much of a response body LogRequest keeps
+// around to log when a request fails (default 4KiB). The cap only bounds
+// the in-memory copy used for logging -- the full body is always written
+// straight through to the client, so streaming endpoints are unaffected.
+func WithMaxLoggedBodyBytes(n int) LogOption {
+	return func(c *logConfig) { c.maxBodyBytes = n }
+}
+
+// WithMetrics records per-request count, latency, and response size to m,
+// labeled by method and status class (e.g. "2xx"). Serve m.Handler()
+// somewhere to expose them to Prometheus.
+func WithMetrics(m *Metrics) LogOption {
+	return func(c *logConfig) { c.metrics = m }
+}
+
+// LogRequest returns a middleware that logs each request through logger and
+// stashes logger in the request context (retrievable with
+// LoggerFromContext) so handlers can log with the same structured fields.
+// It also ensures the request carries an X-Request-ID (generating one if
+// neither RequestID nor the client supplied one) and includes it as a
+// structured log field so requests can be correlated across services.
 // HTTP status  < 400 will be logged as Info
 // HTTP status >= 400 && < 500 will be logged as Info with the body as message
 // HTTP status >= 500 will be logged as Error with the body as message
-func LogRequest(handler http.Handler) http.Handler {
-	return http.HandlerFunc(
-		func(w http.ResponseWriter, r *http.Request) {
-
-			lw := loggedResponse{w: w, started: time.Now()}
-			handler.ServeHTTP(&lw, r)
-
-			lm := log.WithFields(logrus.Fields{
-				"status": lw.status,
-				"remote": r.RemoteAddr,
-				"method": r.Method,
-				"proto":  r.Proto,
-				"uri":    r.RequestURI,
-				"took":   time.Now().Sub(lw.started),
-				"size":   lw.size,
-			})
-			switch {
-			case lw.status < 400:
-				lm.Info(http.StatusText(lw.status))
-			case lw.status >= 400 && lw.status < 500:
-				lm.Info(fmt.Sprintf("%s\n%s", http.StatusText(lw.status), lw.body))
-			default:
-				lm.Error(fmt.Sprintf("%s\n%s", http.StatusText(lw.status), lw.body))
-			}
-		})
-}
+func LogRequest(logger Logger, opts ...LogOption) func(http.Handler) http.Handler {
+	cfg := logConfig{maxBodyBytes: defaultMaxLoggedBodyBytes}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return func(handler http.Handler) http.Handler {
+		return RequestID(http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				// RequestID (wrapping this handler, below) is the single place an
+				// id is generated; it's always present in the context by now.
+				requestID := RequestIDFromContext(r.Context())
+				r = r.WithContext(WithLogger(r.Context(), logger))
 
-func SetLogger(mylog *logrus.Logger) {
-	log = mylog
-}
+				lw := loggedResponse{w: w, started: time.Now(), maxBodyBytes: cfg.maxBodyBytes}
+				handler.ServeHTTP(&lw, r)
+
+				took := time.Now().Sub(lw.started)
+				if cfg.metrics != nil {
+					cfg.metrics.observe(r.Method, lw.status, took, lw.size)
+				}
 
-// RunHTTP starts a webserver with Wrapp logging and panic recovery
-// The port number is fetched from the environment variable SERVICE_PORT
-func RunHTTP(serviceName string, mylog *logrus.Logger, h http.Handler) {
-	servicePort := env.Default("SERVICE_PORT", "8080")
-	SetLogger(mylog)
-	log.Info(fmt.Sprintf("Starting %s on port %s", serviceName, servicePort))
-	log.Fatal(http.ListenAndServe(":"+servicePort, LogRequest(Recover(h))))
+				lm := logger.WithFields(map[string]interface{}{
+					"status":     lw.status,
+					"remote":     r.RemoteAddr,
+					"method":     r.Method,
+					"proto":      r.Proto,
+					"uri":        r.RequestURI,
+					"took":       took,
+					"size":       lw.size,
+					"request_id": requestID,
+				})
+				body := lw.body.String()
+				if lw.status >= 400 {
+					var errResp ErrResponse
+					if json.Unmarshal([]byte(body), &errResp) == nil && errResp.Message != "" {
+						lm = lm.WithFields(map[string]interface{}{
+							"error_code":    errResp.Code,
+							"error_message": errResp.Message,
+						})
+					}
+				}
+				switch {
+				case lw.status < 400:
+					lm.Info(http.StatusText(lw.status))
+				case lw.status >= 400 && lw.status < 500:
+					lm.Info(fmt.Sprintf("%s\n%s", http.StatusText(lw.status), body))
+				default:
+					lm.Error(fmt.Sprintf("%s\n%s", http.StatusText(lw.status), body))
+				}
+			}))
+	}
 }
@@ -0,0 +1,150 @@
+package httplib
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type fakeLogger struct {
+	fields map[string]interface{}
+}
+
+func (l *fakeLogger) Info(args ...interface{})  {}
+func (l *fakeLogger) Error(args ...interface{}) {}
+func (l *fakeLogger) WithFields(fields map[string]interface{}) Logger {
+	l.fields = fields
+	return l
+}
+
+// TestLogRequestReusesRequestID ensures LogRequest and RequestID agree on a
+// single id: a handler composed the documented way (RequestID nested inside
+// LogRequest, e.g. via Server's WithMiddleware) must see the same id in its
+// context as the one LogRequest logs and sets on the response header.
+func TestLogRequestReusesRequestID(t *testing.T) {
+	log := &fakeLogger{}
+
+	var handlerID string
+	inner := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlerID = RequestIDFromContext(r.Context())
+	}))
+
+	handler := LogRequest(log)(inner)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	headerID := w.Header().Get("X-Request-ID")
+	if headerID == "" {
+		t.Fatal("X-Request-ID header not set")
+	}
+	if handlerID != headerID {
+		t.Errorf("handler saw request id %q, response header has %q", handlerID, headerID)
+	}
+	if got := log.fields["request_id"]; got != headerID {
+		t.Errorf("logged request_id = %v, want %q", got, headerID)
+	}
+}
+
+// TestLoggedResponseWriteBuffersOnlyErrors ensures the bounded body buffer is
+// only retained for responses LogRequest actually logs the body of -- a 2xx
+// write should be a pass-through with no copy kept.
+func TestLoggedResponseWriteBuffersOnlyErrors(t *testing.T) {
+	t.Run("2xx", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		lw := loggedResponse{w: w, maxBodyBytes: defaultMaxLoggedBodyBytes}
+		lw.Write([]byte("ok"))
+		if lw.body.Len() != 0 {
+			t.Errorf("body.Len() = %d, want 0 for a 2xx response", lw.body.Len())
+		}
+	})
+
+	t.Run("4xx", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		lw := loggedResponse{w: w, maxBodyBytes: defaultMaxLoggedBodyBytes}
+		lw.WriteHeader(http.StatusBadRequest)
+		lw.Write([]byte("bad request"))
+		if lw.body.String() != "bad request" {
+			t.Errorf("body = %q, want %q", lw.body.String(), "bad request")
+		}
+	})
+}
+
+// TestLoggedResponseWriteRespectsMaxBodyBytes ensures the buffer used for
+// logging never grows past maxBodyBytes, even though the full body is
+// still written through to the real ResponseWriter.
+func TestLoggedResponseWriteRespectsMaxBodyBytes(t *testing.T) {
+	w := httptest.NewRecorder()
+	lw := loggedResponse{w: w, maxBodyBytes: 4}
+	lw.WriteHeader(http.StatusInternalServerError)
+	lw.Write([]byte("a full error body"))
+
+	if lw.body.String() != "a fu" {
+		t.Errorf("body = %q, want it capped at 4 bytes", lw.body.String())
+	}
+	if w.Body.String() != "a full error body" {
+		t.Errorf("underlying writer got %q, want the full body", w.Body.String())
+	}
+}
+
+// TestRecoverWritesGenericErrorWithoutLeakingTraceback ensures a recovered
+// panic's traceback is logged server-side but never sent to the client: the
+// ErrResponse carries the panic message, with no Details.
+func TestRecoverWritesGenericErrorWithoutLeakingTraceback(t *testing.T) {
+	log := &fakeLogger{}
+	handler := Recover(log)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+	if log.fields["traceback"] == nil {
+		t.Error("traceback not logged")
+	}
+	if strings.Contains(w.Body.String(), "goroutine") {
+		t.Error("response body leaked a stack trace to the client")
+	}
+}
+
+type hijackableRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+}
+
+func (h *hijackableRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h.hijacked = true
+	return nil, nil, nil
+}
+
+// TestLoggedResponseHijackProxies ensures Hijack is forwarded to an
+// underlying ResponseWriter that supports it.
+func TestLoggedResponseHijackProxies(t *testing.T) {
+	base := &hijackableRecorder{ResponseRecorder: httptest.NewRecorder()}
+	lw := loggedResponse{w: base}
+
+	if _, _, err := lw.Hijack(); err != nil {
+		t.Fatalf("Hijack: %v", err)
+	}
+	if !base.hijacked {
+		t.Error("Hijack was not proxied to the underlying ResponseWriter")
+	}
+}
+
+// TestLoggedResponseHijackUnsupported ensures Hijack reports an error
+// instead of panicking when the underlying ResponseWriter doesn't support
+// it (httptest.ResponseRecorder doesn't).
+func TestLoggedResponseHijackUnsupported(t *testing.T) {
+	lw := loggedResponse{w: httptest.NewRecorder()}
+	if _, _, err := lw.Hijack(); err == nil {
+		t.Fatal("Hijack: want error for a non-Hijacker ResponseWriter")
+	}
+}